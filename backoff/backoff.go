@@ -0,0 +1,141 @@
+// Copyright @2018 Saddam Hossain.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package backoff provides pluggable backoff strategies for use with the retry package, in place of the
+// hard-coded "+= rand/2; *= 2" jitter the core package used to apply unconditionally.
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Strategy computes the delay to wait before the next attempt, given the attempt number (1-indexed, the
+// attempt that just failed) and the delay used before the previous attempt.
+type Strategy interface {
+	Next(attempt uint, prev time.Duration) time.Duration
+}
+
+// Constant always waits Delay between attempts.
+type Constant struct {
+	Delay time.Duration
+}
+
+// Next implements Strategy.
+func (c Constant) Next(attempt uint, prev time.Duration) time.Duration {
+	return c.Delay
+}
+
+// Linear grows the delay by Step on every attempt, starting from Base, uncapped unless Cap is set.
+type Linear struct {
+	Base time.Duration
+	Step time.Duration
+	Cap  time.Duration
+}
+
+// Next implements Strategy.
+func (l Linear) Next(attempt uint, prev time.Duration) time.Duration {
+	delay := l.Base + l.Step*time.Duration(attempt-1)
+	if l.Cap > 0 && delay > l.Cap {
+		delay = l.Cap
+	}
+	return delay
+}
+
+// Exponential doubles (or grows by Multiplier) the delay on every attempt, starting from Base, capped at
+// Cap. It applies no jitter; pair it with FullJitter or EqualJitter to avoid the thundering herd problem
+// (https://en.wikipedia.org/wiki/Thundering_herd_problem).
+type Exponential struct {
+	Base       time.Duration
+	Multiplier float64
+	Cap        time.Duration
+}
+
+// Next implements Strategy.
+func (e Exponential) Next(attempt uint, prev time.Duration) time.Duration {
+	mult := e.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+
+	delay := prev
+	if delay == 0 {
+		delay = e.Base
+	} else {
+		delay = time.Duration(float64(delay) * mult)
+	}
+	if e.Cap > 0 && delay > e.Cap {
+		delay = e.Cap
+	}
+	return delay
+}
+
+// FullJitter computes an exponential delay and then picks uniformly between zero and that delay, per the
+// "full jitter" strategy from the AWS architecture blog
+// (https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/).
+type FullJitter struct {
+	Base       time.Duration
+	Multiplier float64
+	Cap        time.Duration
+}
+
+// Next implements Strategy.
+func (f FullJitter) Next(attempt uint, prev time.Duration) time.Duration {
+	// prev is the jittered delay actually slept, so the exponential delay is rebuilt from attempt instead.
+	var exp time.Duration
+	for i := uint(1); i <= attempt; i++ {
+		exp = Exponential{Base: f.Base, Multiplier: f.Multiplier, Cap: f.Cap}.Next(i, exp)
+	}
+	if exp <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(exp)))
+}
+
+// EqualJitter computes an exponential delay and randomizes only its upper half, so the result never
+// drops below half of the computed delay. This is the "equal jitter" strategy from the AWS architecture
+// blog (https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/).
+type EqualJitter struct {
+	Base       time.Duration
+	Multiplier float64
+	Cap        time.Duration
+}
+
+// Next implements Strategy.
+func (e EqualJitter) Next(attempt uint, prev time.Duration) time.Duration {
+	// prev is the jittered delay actually slept, so the exponential delay is rebuilt from attempt instead.
+	var exp time.Duration
+	for i := uint(1); i <= attempt; i++ {
+		exp = Exponential{Base: e.Base, Multiplier: e.Multiplier, Cap: e.Cap}.Next(i, exp)
+	}
+	half := exp / 2
+	if half <= 0 {
+		return half
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+// DecorrelatedJitter computes sleep = min(Cap, random_between(Base, prev*3)) on every attempt, per the
+// "decorrelated jitter" strategy from the AWS architecture blog
+// (https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/), which spreads out retries
+// better than the synchronized growth that a plain exponential backoff produces.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Cap  time.Duration
+}
+
+// Next implements Strategy.
+func (d DecorrelatedJitter) Next(attempt uint, prev time.Duration) time.Duration {
+	base := d.Base
+	top := prev * 3
+	if top < base {
+		top = base
+	}
+
+	delay := base + time.Duration(rand.Int63n(int64(top-base)+1))
+	if d.Cap > 0 && delay > d.Cap {
+		delay = d.Cap
+	}
+	return delay
+}
@@ -0,0 +1,75 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConstant(t *testing.T) {
+	c := Constant{Delay: 50 * time.Millisecond}
+	for attempt := uint(1); attempt <= 3; attempt++ {
+		if got := c.Next(attempt, 0); got != 50*time.Millisecond {
+			t.Errorf("attempt %d: expected 50ms, got %s", attempt, got)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	l := Linear{Base: 100 * time.Millisecond, Step: 50 * time.Millisecond, Cap: 220 * time.Millisecond}
+
+	want := []time.Duration{100 * time.Millisecond, 150 * time.Millisecond, 200 * time.Millisecond, 220 * time.Millisecond}
+	for i, w := range want {
+		attempt := uint(i + 1)
+		if got := l.Next(attempt, 0); got != w {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, w, got)
+		}
+	}
+}
+
+func TestExponential(t *testing.T) {
+	e := Exponential{Base: 100 * time.Millisecond, Multiplier: 2, Cap: time.Second}
+
+	var prev time.Duration
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, w := range want {
+		attempt := uint(i + 1)
+		prev = e.Next(attempt, prev)
+		if prev != w {
+			t.Errorf("attempt %d: expected %s, got %s", attempt, w, prev)
+		}
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	f := FullJitter{Base: 100 * time.Millisecond, Multiplier: 2, Cap: time.Second}
+
+	var prev time.Duration
+	for attempt := uint(1); attempt <= 5; attempt++ {
+		got := f.Next(attempt, prev)
+		if got < 0 || got > time.Second {
+			t.Errorf("attempt %d: expected a delay in [0, 1s], got %s", attempt, got)
+		}
+		prev = Exponential{Base: f.Base, Multiplier: f.Multiplier, Cap: f.Cap}.Next(attempt, prev)
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	e := EqualJitter{Base: 100 * time.Millisecond, Multiplier: 2, Cap: time.Second}
+
+	prev := e.Next(1, 0)
+	if prev < 50*time.Millisecond || prev > 100*time.Millisecond {
+		t.Errorf("attempt 1: expected a delay in [50ms, 100ms], got %s", prev)
+	}
+}
+
+func TestDecorrelatedJitter(t *testing.T) {
+	d := DecorrelatedJitter{Base: 100 * time.Millisecond, Cap: time.Second}
+
+	prev := time.Duration(0)
+	for attempt := uint(1); attempt <= 10; attempt++ {
+		prev = d.Next(attempt, prev)
+		if prev < d.Base || prev > d.Cap {
+			t.Errorf("attempt %d: expected a delay in [%s, %s], got %s", attempt, d.Base, d.Cap, prev)
+		}
+	}
+}
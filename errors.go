@@ -0,0 +1,59 @@
+package retry
+
+import (
+	"errors"
+	"strings"
+)
+
+// Error aggregates every error returned by fn across all attempts of a single Do call, in attempt order
+// (followed by ctx.Err() when the context ended the run), so callers can inspect the full history instead of only the last failure. It implements Unwrap() []error
+// so errors.Is and errors.As see through to any individual attempt's error.
+type Error []error
+
+// Error implements the error interface by joining each attempt's message on its own line.
+func (e Error) Error() string {
+	var b strings.Builder
+	for i, err := range e {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Unwrap lets errors.Is and errors.As inspect every attempt's error, not just the last one.
+func (e Error) Unwrap() []error {
+	return e
+}
+
+// unrecoverableError marks an error as permanent: the retry loop stops immediately instead of retrying.
+type unrecoverableError struct {
+	err error
+}
+
+// Error implements the error interface.
+func (u *unrecoverableError) Error() string {
+	return u.err.Error()
+}
+
+// Unwrap lets errors.Is and errors.As see through to the wrapped error.
+func (u *unrecoverableError) Unwrap() error {
+	return u.err
+}
+
+// Unrecoverable wraps err so that a retry loop stops immediately and returns it, instead of retrying
+// further. Use it inside fn to signal a permanent failure, e.g. an HTTP 4xx response that retrying
+// cannot fix.
+func Unrecoverable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &unrecoverableError{err: err}
+}
+
+// IsUnrecoverable reports whether err (or any error it wraps) was produced by Unrecoverable.
+func IsUnrecoverable(err error) bool {
+	var u *unrecoverableError
+	return errors.As(err, &u)
+}
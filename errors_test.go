@@ -0,0 +1,34 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUnrecoverable(t *testing.T) {
+	cause := errors.New("bad request")
+	err := Unrecoverable(cause)
+
+	if !IsUnrecoverable(err) {
+		t.Error("Expected IsUnrecoverable to report true")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected errors.Is to see through to the wrapped cause")
+	}
+}
+
+func TestIsUnrecoverable_PlainError(t *testing.T) {
+	if IsUnrecoverable(errors.New("transient")) {
+		t.Error("Expected a plain error to not be reported as unrecoverable")
+	}
+}
+
+func TestError_Unwrap(t *testing.T) {
+	e1 := errors.New("one")
+	e2 := errors.New("two")
+	err := Error{e1, e2}
+
+	if !errors.Is(err, e1) || !errors.Is(err, e2) {
+		t.Error("Expected errors.Is to find both aggregated errors")
+	}
+}
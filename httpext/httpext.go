@@ -0,0 +1,191 @@
+// Copyright @2018 Saddam Hossain.  All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package httpext adapts the retry package to net/http, so real API clients can retry transient failures
+// without hand-rolling a loop over Retry-After headers, body re-cloning, and idempotency rules.
+package httpext
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/superchalupa/retry/backoff"
+)
+
+// defaultRetryableStatus is the set of status codes DoRequest retries by default: 429 (Too Many
+// Requests) and the gateway/availability codes 502, 503 and 504.
+var defaultRetryableStatus = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotentMethods are retried by default even when the request carries no body; non-idempotent
+// methods such as POST and PATCH require WithAllowNonIdempotent to be retried.
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+type config struct {
+	attempts           uint
+	backoff            backoff.Strategy
+	statusCodes        map[int]bool
+	maxRetryAfter      time.Duration
+	allowNonIdempotent bool
+}
+
+// Option configures DoRequest.
+type Option func(*config)
+
+// WithAttempts sets the maximum number of attempts, including the first one. The default is 3.
+func WithAttempts(attempts uint) Option {
+	return func(c *config) { c.attempts = attempts }
+}
+
+// WithBackoff overrides the default backoff strategy (backoff.FullJitter), used whenever a response
+// carries no usable Retry-After header.
+func WithBackoff(strategy backoff.Strategy) Option {
+	return func(c *config) { c.backoff = strategy }
+}
+
+// WithStatusCodes overrides the set of response status codes considered retryable. The default is
+// 429, 502, 503 and 504.
+func WithStatusCodes(codes ...int) Option {
+	return func(c *config) {
+		m := make(map[int]bool, len(codes))
+		for _, code := range codes {
+			m[code] = true
+		}
+		c.statusCodes = m
+	}
+}
+
+// WithMaxRetryAfter caps how long DoRequest will honor a Retry-After header. The default is 60s;
+// a server asking for a longer wait is capped rather than trusted blindly.
+func WithMaxRetryAfter(d time.Duration) Option {
+	return func(c *config) { c.maxRetryAfter = d }
+}
+
+// WithAllowNonIdempotent allows DoRequest to retry methods that are not idempotent (e.g. POST, PATCH).
+// By default such requests are sent exactly once, since retrying them can duplicate side effects.
+func WithAllowNonIdempotent() Option {
+	return func(c *config) { c.allowNonIdempotent = true }
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		attempts:      3,
+		backoff:       backoff.FullJitter{Base: 200 * time.Millisecond, Multiplier: 2, Cap: 30 * time.Second},
+		statusCodes:   defaultRetryableStatus,
+		maxRetryAfter: 60 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// DoRequest sends req with client, retrying on network errors and on the configured retryable status
+// codes (429, 502, 503, 504 by default). A response carrying a Retry-After header overrides the backoff
+// for that attempt, parsed as either delta-seconds or an HTTP-date and capped by WithMaxRetryAfter.
+//
+// Between attempts the request body is re-created from req.GetBody, and any prior response body is
+// drained and closed. Only idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT, DELETE) are retried
+// unless WithAllowNonIdempotent is passed; other methods and requests with a body but no GetBody are
+// sent exactly once.
+//
+// DoRequest returns a non-nil error only for a network-level failure on the final attempt, for a failure
+// of req.GetBody, or for ctx being done; an exhausted retryable status code is returned as a normal response for the caller to
+// inspect, the same as http.Client.Do would.
+func DoRequest(ctx context.Context, client *http.Client, req *http.Request, opts ...Option) (*http.Response, error) {
+	cfg := newConfig(opts...)
+
+	canCloneBody := req.Body == nil || req.GetBody != nil
+	retryable := (cfg.allowNonIdempotent || idempotentMethods[req.Method]) && canCloneBody
+
+	var prevResp *http.Response
+	var sleep time.Duration
+
+	for attempt := uint(1); ; attempt++ {
+		if prevResp != nil {
+			io.Copy(io.Discard, prevResp.Body)
+			prevResp.Body.Close()
+			prevResp = nil
+		}
+
+		attemptReq := req.Clone(ctx)
+		if req.Body != nil && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := client.Do(attemptReq)
+		if err != nil {
+			if !retryable || attempt >= cfg.attempts {
+				return nil, err
+			}
+			sleep = cfg.backoff.Next(attempt, sleep)
+		} else if !retryable || !cfg.statusCodes[resp.StatusCode] || attempt >= cfg.attempts {
+			return resp, nil
+		} else {
+			prevResp = resp
+			if ra := parseRetryAfter(resp.Header.Get("Retry-After"), cfg.maxRetryAfter); ra > 0 {
+				sleep = ra
+			} else {
+				sleep = cfg.backoff.Next(attempt, sleep)
+			}
+		}
+
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if prevResp != nil {
+				prevResp.Body.Close()
+			}
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value as either delta-seconds or an HTTP-date, returning 0
+// if it is absent or unparseable, and capping the result at max if max > 0.
+func parseRetryAfter(header string, max time.Duration) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	var delay time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs <= 0 {
+			return 0
+		}
+		delay = time.Duration(secs) * time.Second
+	} else if when, err := http.ParseTime(header); err == nil {
+		delay = time.Until(when)
+		if delay <= 0 {
+			return 0
+		}
+	} else {
+		return 0
+	}
+
+	if max > 0 && delay > max {
+		delay = max
+	}
+	return delay
+}
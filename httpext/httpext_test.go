@@ -0,0 +1,206 @@
+package httpext
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoRequest_RetriesRetryableStatus(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := DoRequest(context.Background(), srv.Client(), req, WithAttempts(5), WithBackoff(zeroBackoff{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDoRequest_HonorsRetryAfter(t *testing.T) {
+	var calls int32
+	var firstCallAt, secondCallAt time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			firstCallAt = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		secondCallAt = time.Now()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := DoRequest(context.Background(), srv.Client(), req, WithAttempts(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if gap := secondCallAt.Sub(firstCallAt); gap < time.Second {
+		t.Errorf("Expected the retry to wait at least the advertised 1s, waited %s", gap)
+	}
+}
+
+func TestDoRequest_RetriesNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := srv.Client()
+	rt := &flakyRoundTripper{transport: client.Transport, failures: 2}
+	client.Transport = rt
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	resp, err := DoRequest(context.Background(), client, req, WithAttempts(5), WithBackoff(zeroBackoff{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200, got %d", resp.StatusCode)
+	}
+	if rt.calls != 3 {
+		t.Errorf("Expected 3 calls (2 network errors + 1 success), got %d", rt.calls)
+	}
+}
+
+func TestDoRequest_ClonesBodyBetweenAttempts(t *testing.T) {
+	const payload = "hello retry"
+
+	var calls int32
+	var bodyMismatch bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != payload {
+			bodyMismatch = true
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(payload))
+	resp, err := DoRequest(context.Background(), srv.Client(), req, WithAttempts(5), WithBackoff(zeroBackoff{}), WithAllowNonIdempotent())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if bodyMismatch {
+		t.Error("Expected every attempt to see the same request body via req.GetBody")
+	}
+}
+
+func TestDoRequest_NonIdempotentNotRetriedByDefault(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	resp, err := DoRequest(context.Background(), srv.Client(), req, WithAttempts(5), WithBackoff(zeroBackoff{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 1 {
+		t.Errorf("Expected a non-idempotent request to be sent exactly once, got %d calls", calls)
+	}
+}
+
+func TestDoRequest_BodyWithoutGetBodySentOnce(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, strings.NewReader("payload"))
+	req.GetBody = nil
+	resp, err := DoRequest(context.Background(), srv.Client(), req, WithAttempts(5), WithBackoff(zeroBackoff{}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if calls != 1 {
+		t.Errorf("Expected a request with a body but no GetBody to be sent exactly once, got %d calls", calls)
+	}
+}
+
+func TestParseRetryAfter_Seconds(t *testing.T) {
+	got := parseRetryAfter("5", time.Minute)
+	if got != 5*time.Second {
+		t.Errorf("Expected 5s, got %s", got)
+	}
+}
+
+func TestParseRetryAfter_CappedByMax(t *testing.T) {
+	got := parseRetryAfter(strconv.Itoa(3600), time.Minute)
+	if got != time.Minute {
+		t.Errorf("Expected the delay to be capped at 1m, got %s", got)
+	}
+}
+
+func TestParseRetryAfter_Invalid(t *testing.T) {
+	if got := parseRetryAfter("not-a-valid-value", time.Minute); got != 0 {
+		t.Errorf("Expected 0 for an unparseable header, got %s", got)
+	}
+}
+
+type zeroBackoff struct{}
+
+func (zeroBackoff) Next(attempt uint, prev time.Duration) time.Duration { return 0 }
+
+// flakyRoundTripper simulates a network error on the first `failures` requests before delegating to the
+// wrapped transport, so tests can exercise DoRequest's handling of client.Do errors rather than just
+// retryable status codes.
+type flakyRoundTripper struct {
+	transport http.RoundTripper
+	failures  int32
+	calls     int32
+}
+
+func (f *flakyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return nil, errors.New("simulated network error")
+	}
+	return f.transport.RoundTrip(req)
+}
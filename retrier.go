@@ -0,0 +1,186 @@
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// BackoffFunc computes the delay to wait before the next attempt, given the attempt number
+// (1-indexed, the attempt that just failed) and the previous delay used.
+type BackoffFunc func(attempt uint, prev time.Duration) time.Duration
+
+// Retrier holds a reusable retry policy built with New and a list of Options. A single Retrier can be
+// shared across many call sites instead of repeating attempt/sleep/backoff arguments at each one.
+type Retrier struct {
+	attempts     uint
+	minDelay     time.Duration
+	maxDelay     time.Duration
+	maxTotalTime time.Duration
+	multiplier   float64
+	jitter       float64
+	backoff      BackoffFunc
+	onRetry      func(attempt uint, err error)
+	retryIf      func(error) bool
+	abortIf      func(error) bool
+}
+
+// Option configures a Retrier built by New.
+type Option func(*Retrier)
+
+// WithAttempts sets the maximum number of attempts, including the first one. The default is 3.
+func WithAttempts(attempts uint) Option {
+	return func(r *Retrier) { r.attempts = attempts }
+}
+
+// WithMaxTotalTime bounds the wall-clock time spent across all attempts and sleeps. Once the budget is
+// exhausted, Do returns its aggregated Error instead of sleeping further. The default is no limit.
+func WithMaxTotalTime(d time.Duration) Option {
+	return func(r *Retrier) { r.maxTotalTime = d }
+}
+
+// WithMinDelay sets the delay before the second attempt, before jitter is added, i.e. the starting point
+// the default backoff grows from. It is not used when WithBackoff is set. The default is 100ms.
+func WithMinDelay(d time.Duration) Option {
+	return func(r *Retrier) { r.minDelay = d }
+}
+
+// WithMaxDelay caps the delay computed between any two attempts. The default is no cap, which is a real
+// footgun with unbounded exponential growth: with the default backoff, a 10-attempt run starting at 1s
+// sleeps at least 256s (over 4 minutes) on its final gap.
+func WithMaxDelay(d time.Duration) Option {
+	return func(r *Retrier) { r.maxDelay = d }
+}
+
+// WithMultiplier sets the growth factor applied to the delay by the default backoff. The default is 2.0.
+func WithMultiplier(m float64) Option {
+	return func(r *Retrier) { r.multiplier = m }
+}
+
+// WithJitter sets the fraction of the computed delay, in [0, 1], that is randomized to avoid the
+// thundering herd problem (https://en.wikipedia.org/wiki/Thundering_herd_problem). The default is 0.5.
+func WithJitter(j float64) Option {
+	return func(r *Retrier) { r.jitter = j }
+}
+
+// WithBackoff overrides the default exponential-with-jitter backoff with a custom BackoffFunc, e.g. one
+// of the strategies in the retry/backoff subpackage.
+func WithBackoff(fn BackoffFunc) Option {
+	return func(r *Retrier) { r.backoff = fn }
+}
+
+// WithOnRetry registers a callback invoked after each failed attempt that Do may retry (attempts remain and
+// neither Unrecoverable, WithRetryIf nor WithAbortIf stops it), before the MaxTotalTime check and the sleep,
+// with the attempt number that just failed and the error it returned. Useful for logging and metrics.
+func WithOnRetry(fn func(attempt uint, err error)) Option {
+	return func(r *Retrier) { r.onRetry = fn }
+}
+
+// WithRetryIf restricts retries to errors for which fn returns true. Errors for which fn returns false
+// are returned immediately without further attempts. The default retries every error. See also WithAbortIf
+// and Unrecoverable, which express the same idea the other way round.
+func WithRetryIf(fn func(error) bool) Option {
+	return func(r *Retrier) { r.retryIf = fn }
+}
+
+// WithAbortIf stops retrying immediately, without further attempts, when fn returns true for the error
+// an attempt returned. It is the inverse of WithRetryIf; use whichever reads more naturally for the
+// classification at hand. The default never aborts early based on the error's value.
+func WithAbortIf(fn func(error) bool) Option {
+	return func(r *Retrier) { r.abortIf = fn }
+}
+
+// New builds a Retrier from the given Options, falling back to sensible defaults for anything not set.
+func New(opts ...Option) *Retrier {
+	r := &Retrier{
+		attempts:   3,
+		minDelay:   100 * time.Millisecond,
+		multiplier: 2.0,
+		jitter:     0.5,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *Retrier) nextDelay(attempt uint, prev time.Duration) time.Duration {
+	var sleep time.Duration
+	if r.backoff != nil {
+		sleep = r.backoff(attempt, prev)
+	} else {
+		sleep = prev
+		if sleep == 0 {
+			sleep = r.minDelay
+		} else {
+			sleep = time.Duration(float64(sleep) * r.multiplier)
+		}
+		if jitterRange := int64(float64(sleep) * r.jitter); r.jitter > 0 && jitterRange > 0 {
+			sleep += time.Duration(rand.Int63n(jitterRange))
+		}
+	}
+	if r.maxDelay > 0 && sleep > r.maxDelay {
+		sleep = r.maxDelay
+	}
+	return sleep
+}
+
+// Do runs fn until it succeeds, attempts are exhausted, ctx is done, or the MaxTotalTime budget runs out,
+// sleeping between attempts according to the configured backoff. It aborts immediately, without further
+// attempts, if ctx is cancelled or its deadline expires, if fn returns an Unrecoverable error, or if
+// WithRetryIf/WithAbortIf say to stop. On failure the returned error is an Error aggregating every
+// attempt's error in order, followed by ctx.Err() when the context ended the run; errors.Is and errors.As
+// see through it to any of them.
+func (r *Retrier) Do(ctx context.Context, fn func(context.Context) error) error {
+	start := time.Now()
+	var errs Error
+	var sleep time.Duration
+
+	for attempt := uint(1); ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+
+		if IsUnrecoverable(err) {
+			return errs
+		}
+		if r.retryIf != nil && !r.retryIf(err) {
+			return errs
+		}
+		if r.abortIf != nil && r.abortIf(err) {
+			return errs
+		}
+		if r.attempts > 0 && attempt >= r.attempts {
+			return errs
+		}
+
+		if r.onRetry != nil {
+			r.onRetry(attempt, err)
+		}
+
+		sleep = r.nextDelay(attempt, sleep)
+		if r.maxTotalTime > 0 {
+			remaining := r.maxTotalTime - time.Since(start)
+			if remaining <= 0 {
+				return errs
+			}
+			if sleep > remaining {
+				sleep = remaining
+			}
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return append(errs, ctxErr)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			errs = append(errs, ctx.Err())
+			return errs
+		case <-timer.C:
+		}
+	}
+}
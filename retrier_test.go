@@ -0,0 +1,186 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetrierDo(t *testing.T) {
+	var try = 0
+	r := New(WithAttempts(5), WithMinDelay(0))
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		try++
+		if try < 5 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+
+	if err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if try != 5 {
+		t.Errorf("Expected 5 attempts, got %d", try)
+	}
+}
+
+func TestRetrierDo_AttemptsExhausted(t *testing.T) {
+	var try = 0
+	r := New(WithAttempts(3), WithMinDelay(0))
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		try++
+		return errors.New("always fails")
+	})
+
+	if err == nil {
+		t.Error("Expected an error once attempts are exhausted")
+	}
+	if try != 3 {
+		t.Errorf("Expected 3 attempts, got %d", try)
+	}
+}
+
+func TestRetrierDo_RetryIf(t *testing.T) {
+	permanent := errors.New("permanent")
+	var try = 0
+	r := New(
+		WithAttempts(5),
+		WithMinDelay(0),
+		WithRetryIf(func(err error) bool { return !errors.Is(err, permanent) }),
+	)
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		try++
+		return permanent
+	})
+
+	if !errors.Is(err, permanent) {
+		t.Error("Expected the permanent error to be returned", err)
+	}
+	if try != 1 {
+		t.Errorf("Expected retry to stop after the first attempt, ran %d times", try)
+	}
+}
+
+func TestRetrierDo_OnRetry(t *testing.T) {
+	var seen []uint
+	r := New(
+		WithAttempts(3),
+		WithMinDelay(0),
+		WithOnRetry(func(attempt uint, err error) { seen = append(seen, attempt) }),
+	)
+	_ = r.Do(context.Background(), func(ctx context.Context) error {
+		return errors.New("always fails")
+	})
+
+	if len(seen) != 2 {
+		t.Errorf("Expected onRetry to fire twice (once per attempt that triggers a retry), got %v", seen)
+	}
+}
+
+func TestRetrierDo_AbortIf(t *testing.T) {
+	notFound := errors.New("404 not found")
+	var try = 0
+	r := New(
+		WithAttempts(5),
+		WithMinDelay(0),
+		WithAbortIf(func(err error) bool { return errors.Is(err, notFound) }),
+	)
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		try++
+		return notFound
+	})
+
+	if !errors.Is(err, notFound) {
+		t.Error("Expected the 404 error to be returned", err)
+	}
+	if try != 1 {
+		t.Errorf("Expected retry to stop after the first attempt, ran %d times", try)
+	}
+}
+
+func TestRetrierDo_Unrecoverable(t *testing.T) {
+	var try = 0
+	r := New(WithAttempts(5), WithMinDelay(0))
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		try++
+		return Unrecoverable(errors.New("bad request"))
+	})
+
+	if !IsUnrecoverable(err) {
+		t.Error("Expected the returned error to be unrecoverable", err)
+	}
+	if try != 1 {
+		t.Errorf("Expected retry to stop after the first attempt, ran %d times", try)
+	}
+}
+
+func TestRetrierDo_AggregatedErrors(t *testing.T) {
+	r := New(WithAttempts(3), WithMinDelay(0))
+	var n int
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		n++
+		return fmt.Errorf("attempt %d failed", n)
+	})
+
+	var retryErr Error
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Expected err to be a retry.Error, got %T: %v", err, err)
+	}
+	if len(retryErr) != 3 {
+		t.Errorf("Expected 3 aggregated errors, got %d", len(retryErr))
+	}
+}
+
+func TestRetrierDo_MaxTotalTime(t *testing.T) {
+	r := New(
+		WithAttempts(100),
+		WithMinDelay(10*time.Millisecond),
+		WithJitter(0),
+		WithMaxTotalTime(15*time.Millisecond),
+	)
+
+	start := time.Now()
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		return errors.New("always fails")
+	})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Error("Expected an error once the time budget is exhausted")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Expected MaxTotalTime to bound the run, took %s", elapsed)
+	}
+}
+
+func TestRetrierDo_MaxDelay(t *testing.T) {
+	r := New(
+		WithAttempts(4),
+		WithMinDelay(5*time.Millisecond),
+		WithMultiplier(10),
+		WithJitter(0),
+		WithMaxDelay(20*time.Millisecond),
+	)
+
+	var prevCall time.Time
+	var maxObserved time.Duration
+	err := r.Do(context.Background(), func(ctx context.Context) error {
+		if !prevCall.IsZero() {
+			if gap := time.Since(prevCall); gap > maxObserved {
+				maxObserved = gap
+			}
+		}
+		prevCall = time.Now()
+		return errors.New("always fails")
+	})
+
+	if err == nil {
+		t.Error("Expected an error once attempts are exhausted")
+	}
+	if maxObserved > 100*time.Millisecond {
+		t.Errorf("Expected MaxDelay to cap each sleep around 20ms, observed a gap of %s", maxObserved)
+	}
+}
@@ -6,6 +6,7 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"math/rand"
 	"reflect"
@@ -16,34 +17,62 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// DoFuncBAckoff runs provided func until it returns nil error or attempts exhausted, running the provided backoff strategy function between successive attempts
-func DoFuncBackoff(attempt uint, sleep time.Duration, backoffStrategy func(time.Duration) time.Duration, fn func() error) (err error) {
-	for {
-		err = fn(); 
-    if attempt--; attempt == 0 || err == nil{
-      break
-    }
-    sleep = backoffStrategy(sleep)
+// DoFuncBackoff runs provided func until it returns nil error or attempts exhausted, sleeping between
+// successive attempts for as long as backoffStrategy computes, given the attempt that just failed and
+// the previous sleep. Strategies from the retry/backoff subpackage satisfy this signature directly, e.g.
+// backoff.DecorrelatedJitter{Base: 100 * time.Millisecond, Cap: 30 * time.Second}.Next.
+//
+// maxDelay and maxTotalTime bound the computed delay and the overall wall-clock time of the run, attempts
+// included; zero means unbounded. Without a cap, an unlucky backoff strategy can produce a very long final
+// sleep (e.g. a 10-attempt run that doubles from 1s sleeps 256s, over 4 minutes, on its last gap) for no
+// benefit once the caller has long since stopped waiting. When the maxTotalTime budget runs out, DoFuncBackoff stops early
+// and returns the last error instead of sleeping through it.
+func DoFuncBackoff(attempt uint, sleep time.Duration, maxDelay time.Duration, maxTotalTime time.Duration, backoffStrategy func(attempt uint, prev time.Duration) time.Duration, fn func() error) (err error) {
+	start := time.Now()
+	for n := uint(1); ; n++ {
+		err = fn()
+		if attempt--; attempt == 0 || err == nil {
+			break
+		}
+
+		sleep = backoffStrategy(n, sleep)
+		if maxDelay > 0 && sleep > maxDelay {
+			sleep = maxDelay
+		}
+		if maxTotalTime > 0 {
+			remaining := maxTotalTime - time.Since(start)
+			if remaining <= 0 {
+				break
+			}
+			if sleep > remaining {
+				sleep = remaining
+			}
+		}
+		time.Sleep(sleep)
 	}
 	return err
 }
 
 // DoFunc try to execute the function, it only expect that the function will return an error only
-// default backoff strategy is exponential 
+// default backoff strategy is exponential. DoFunc is a thin wrapper around a one-off Retrier built with
+// the equivalent options; prefer New if you want to reuse the same policy across many calls.
 func DoFunc(attempt uint, sleep time.Duration, fn func() error) (err error) {
-  backoff := func(sleep time.Duration) time.Duration {
-		// Add jitter to prevent Thundering Herd problem (https://en.wikipedia.org/wiki/Thundering_herd_problem)
-		sleep += (time.Duration(rand.Int63n(int64(sleep)))) / 2
-		time.Sleep(sleep)
-		// multiplicative for next loop
-		return 2*sleep
-  }
-  
-  return DoFuncBackoff(attempt, sleep, backoff, fn)
+	r := New(WithAttempts(attempt), WithMinDelay(sleep), WithMultiplier(2.0), WithJitter(0.5))
+	return r.Do(context.Background(), func(context.Context) error { return fn() })
 }
 
+// errFnReturnNotError is returned internally by Do when fn's right-most return value is not an error,
+// so that the Retrier backing it aborts immediately instead of retrying a call that can never succeed.
+var errFnReturnNotError = errors.New("retry: fn return's right most value must be an error")
+
+// errorType is used to check, statically, whether fn's right-most return value is of a type that can
+// ever be an error at all, as opposed to merely holding a non-nil value of some unrelated type (e.g. a
+// plain bool) on a given call.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // Do try to execute the function by its value, function can take variadic arguments and return multiple return.
-// You must put error as the last return value so that DoFunc can take decision that the call failed or not
+// You must put error as the last return value so that Do can take decision that the call failed or not.
+// Like DoFunc, Do is a thin wrapper around a one-off Retrier.
 func Do(attempt uint, sleep time.Duration, fn interface{}, args ...interface{}) ([]interface{}, error) {
 
 	if attempt == 0 {
@@ -73,6 +102,141 @@ func Do(attempt uint, sleep time.Duration, fn interface{}, args ...interface{})
 		in[k] = reflect.ValueOf(a)
 	}
 
+	// fn's right-most return value can never be an error, so there is nothing to retry on: call it once
+	// and hand back every value as-is.
+	if !vfn.Type().Out(vfn.Type().NumOut() - 1).Implements(errorType) {
+		out := make([]interface{}, 0, vfn.Type().NumOut())
+		for _, o := range vfn.Call(in) {
+			out = append(out, o.Interface())
+		}
+		return out, nil
+	}
+
+	var out []interface{}
+	r := New(WithAttempts(attempt), WithMinDelay(sleep), WithMultiplier(2.0), WithJitter(0.5),
+		WithRetryIf(func(err error) bool { return err != errFnReturnNotError }))
+
+	err := r.Do(context.Background(), func(context.Context) error {
+		out = nil
+		for _, o := range vfn.Call(in) {
+			out = append(out, o.Interface())
+		}
+
+		// if the last value is not error then return an error
+		callErr, ok := out[len(out)-1].(error)
+		if !ok && out[len(out)-1] != nil {
+			return errFnReturnNotError
+		}
+		return callErr
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return out[:len(out)-1], nil
+}
+
+// DoFuncContext runs provided func until it returns nil error or attempts exhausted, using the default
+// exponential backoff strategy between successive attempts. Unlike DoFunc, it aborts immediately if ctx
+// is cancelled or its deadline expires, instead of sleeping through a caller that has already given up.
+// The context error is joined with the last error returned by fn. fn always runs at least once, even if
+// ctx is already done when DoFuncContext is called; only the sleep between attempts is ctx-aware.
+//
+// As with DoFuncBackoff, maxDelay and maxTotalTime bound the computed delay and the overall wall-clock
+// time of the run, attempts included; zero means unbounded. When the maxTotalTime budget runs out, DoFuncContext stops
+// early and returns the last error instead of sleeping through it.
+func DoFuncContext(ctx context.Context, attempt uint, sleep time.Duration, maxDelay time.Duration, maxTotalTime time.Duration, fn func(context.Context) error) (err error) {
+	start := time.Now()
+	for {
+		err = fn(ctx)
+		if attempt--; attempt == 0 || err == nil {
+			break
+		}
+
+		// Add jitter to prevent Thundering Herd problem (https://en.wikipedia.org/wiki/Thundering_herd_problem)
+		if sleep > 0 {
+			sleep += (time.Duration(rand.Int63n(int64(sleep)))) / 2
+		}
+		if maxDelay > 0 && sleep > maxDelay {
+			sleep = maxDelay
+		}
+		if maxTotalTime > 0 {
+			remaining := maxTotalTime - time.Since(start)
+			if remaining <= 0 {
+				break
+			}
+			if sleep > remaining {
+				sleep = remaining
+			}
+		}
+
+		if ctx.Err() != nil {
+			return errors.Join(ctx.Err(), err)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+
+		// multiplicative for next loop
+		sleep *= 2
+	}
+
+	if err != nil && ctx.Err() != nil {
+		return errors.Join(ctx.Err(), err)
+	}
+	return err
+}
+
+// DoContext is the context-aware, reflective variant of Do. It retries fn, which may take any arguments
+// and must return an error as its last value, until it succeeds, attempts are exhausted, or ctx is done.
+// As with DoFuncContext, fn always runs at least once, even if ctx is already done when DoContext is
+// called; only the sleep between attempts is ctx-aware, and ctx.Err() is joined with the last error
+// from fn once it fires. maxDelay and maxTotalTime bound the sleep the same way they do in
+// DoFuncContext; zero means unbounded.
+func DoContext(ctx context.Context, attempt uint, sleep time.Duration, maxDelay time.Duration, maxTotalTime time.Duration, fn interface{}, args ...interface{}) ([]interface{}, error) {
+
+	if attempt == 0 {
+		return nil, errors.New("retry: attempt should be greater than 0")
+	}
+
+	vfn := reflect.ValueOf(fn)
+
+	// if the fn is not a function then return error
+	if vfn.Type().Kind() != reflect.Func {
+		return nil, errors.New("retry: fn is not a function")
+	}
+
+	// if the functions in not variadic then return the argument missmatch error
+	if !vfn.Type().IsVariadic() && (vfn.Type().NumIn() != len(args)) {
+		return nil, errors.New("retry: fn argument mismatch")
+	}
+
+	// if the function does not return anything, we can't catch if an error occur or not
+	if vfn.Type().NumOut() <= 0 {
+		return nil, errors.New("retry: fn return's can not empty, at least an error")
+	}
+
+	// build args for reflect value Call
+	in := make([]reflect.Value, len(args))
+	for k, a := range args {
+		in[k] = reflect.ValueOf(a)
+	}
+
+	// fn's right-most return value can never be an error, so there is nothing to retry on: call it once
+	// and hand back every value as-is.
+	if !vfn.Type().Out(vfn.Type().NumOut() - 1).Implements(errorType) {
+		out := make([]interface{}, 0, vfn.Type().NumOut())
+		for _, o := range vfn.Call(in) {
+			out = append(out, o.Interface())
+		}
+		return out, nil
+	}
+
+	start := time.Now()
 	var lastErr error
 	for attempt > 0 {
 		// call the fn with arguments
@@ -92,11 +256,44 @@ func Do(attempt uint, sleep time.Duration, fn interface{}, args ...interface{})
 		}
 		lastErr = err
 		attempt--
+
+		if attempt == 0 {
+			break
+		}
+
 		// Add jitter to prevent Thundering Herd problem (https://en.wikipedia.org/wiki/Thundering_herd_problem)
-		sleep += (time.Duration(rand.Int63n(int64(sleep)))) / 2
-		time.Sleep(sleep)
+		if sleep > 0 {
+			sleep += (time.Duration(rand.Int63n(int64(sleep)))) / 2
+		}
+		if maxDelay > 0 && sleep > maxDelay {
+			sleep = maxDelay
+		}
+		if maxTotalTime > 0 {
+			remaining := maxTotalTime - time.Since(start)
+			if remaining <= 0 {
+				break
+			}
+			if sleep > remaining {
+				sleep = remaining
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil, errors.Join(ctx.Err(), lastErr)
+		}
+		timer := time.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+
 		sleep *= 2
 	}
 
+	if ctx.Err() != nil {
+		return nil, errors.Join(ctx.Err(), lastErr)
+	}
 	return nil, lastErr
 }
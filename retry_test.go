@@ -1,10 +1,13 @@
 package retry
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/superchalupa/retry/backoff"
 )
 
 func TestDoFunc(t *testing.T) {
@@ -124,9 +127,176 @@ func TestDo(t *testing.T) {
 		}
 
 		if !tc.ExpectedError && out != nil {
-			if out[0].Interface() != tc.Result {
-				t.Errorf("Failed: %s \nExpected: %v \nGot: %v", tc.Tag, tc.Result, out[0].Interface())
+			if out[0] != tc.Result {
+				t.Errorf("Failed: %s \nExpected: %v \nGot: %v", tc.Tag, tc.Result, out[0])
+			}
+		}
+	}
+}
+
+func TestDoFuncBackoff(t *testing.T) {
+	var try = 0
+	strategy := backoff.Constant{Delay: time.Millisecond}
+	_ = DoFuncBackoff(5, 0, 0, 0, strategy.Next, func() error {
+		if try < 5 {
+			try++
+			return errors.New("Try is not five")
+		}
+		return nil
+	})
+	if try != 5 {
+		t.Error("Retry failed, expected try = 5")
+	}
+}
+
+func TestDoFuncBackoff_MaxTotalTime(t *testing.T) {
+	strategy := backoff.Exponential{Base: 10 * time.Millisecond, Multiplier: 2}
+
+	start := time.Now()
+	err := DoFuncBackoff(100, 0, 0, 15*time.Millisecond, strategy.Next, func() error {
+		return errors.New("always fails")
+	})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Error("Expected an error once the time budget is exhausted")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Expected maxTotalTime to bound the run, took %s", elapsed)
+	}
+}
+
+func TestDoFuncBackoff_MaxDelay(t *testing.T) {
+	strategy := backoff.Exponential{Base: 10 * time.Millisecond, Multiplier: 10}
+
+	var try int
+	var maxObserved time.Duration
+	prevCall := time.Now()
+	_ = DoFuncBackoff(4, 0, 20*time.Millisecond, 0, func(attempt uint, prev time.Duration) time.Duration {
+		d := strategy.Next(attempt, prev)
+		return d
+	}, func() error {
+		if try > 0 {
+			gap := time.Since(prevCall)
+			if gap > maxObserved {
+				maxObserved = gap
 			}
 		}
+		prevCall = time.Now()
+		try++
+		return errors.New("always fails")
+	})
+
+	if maxObserved > 100*time.Millisecond {
+		t.Errorf("Expected maxDelay to cap each sleep around 20ms, observed a gap of %s", maxObserved)
+	}
+}
+
+func TestDoFuncContext(t *testing.T) {
+	var try = 0
+	_ = DoFuncContext(context.Background(), 5, 0, 0, 0, func(ctx context.Context) error {
+		if try < 5 {
+			try++
+			return errors.New("Try is not five")
+		}
+		return nil
+	})
+	if try != 5 {
+		t.Error("Retry failed, expected try = 5")
+	}
+}
+
+func TestDoFuncContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var try = 0
+	err := DoFuncContext(ctx, 5, 10*time.Millisecond, 0, 0, func(ctx context.Context) error {
+		try++
+		return errors.New("always fails")
+	})
+
+	if try != 1 {
+		t.Errorf("Expected fn to run exactly once before the cancellation was observed, ran %d times", try)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected returned error to wrap context.Canceled, got: %v", err)
+	}
+}
+
+func TestDoFuncContext_SucceedsDespiteCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := DoFuncContext(ctx, 3, 0, 0, 0, func(ctx context.Context) error {
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("Expected a successful fn to report success even if ctx was cancelled, got: %v", err)
+	}
+}
+
+func TestDoFuncContext_MaxTotalTime(t *testing.T) {
+	start := time.Now()
+	err := DoFuncContext(context.Background(), 100, 10*time.Millisecond, 0, 15*time.Millisecond, func(ctx context.Context) error {
+		return errors.New("always fails")
+	})
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Error("Expected an error once the time budget is exhausted")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Expected maxTotalTime to bound the run, took %s", elapsed)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	sum := func(nums ...int) (int, error) {
+		var result int
+		for _, n := range nums {
+			result = result + n
+		}
+		return result, nil
+	}
+
+	out, err := DoContext(context.Background(), 2, 1*time.Millisecond, 0, 0, sum, 1, 2, 3, 4)
+	if err != nil {
+		t.Error("Unexpected error", err)
+	}
+	if out[0] != 10 {
+		t.Errorf("Expected 10, got %v", out[0])
+	}
+}
+
+func TestDoContext_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	div := func(a, b float64) (float64, error) {
+		return 0, errors.New("Can not divide by zero")
+	}
+
+	_, err := DoContext(ctx, 5, 10*time.Millisecond, 0, 0, div, 9.0, 0.0)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected returned error to wrap context.Canceled, got: %v", err)
+	}
+}
+
+func TestDoContext_MaxTotalTime(t *testing.T) {
+	div := func(a, b float64) (float64, error) {
+		return 0, errors.New("Can not divide by zero")
+	}
+
+	start := time.Now()
+	_, err := DoContext(context.Background(), 100, 10*time.Millisecond, 0, 15*time.Millisecond, div, 9.0, 0.0)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Error("Expected an error once the time budget is exhausted")
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Errorf("Expected maxTotalTime to bound the run, took %s", elapsed)
 	}
 }